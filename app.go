@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rivo/tview"
+
+	"pg_tui/catalog"
+	"pg_tui/history"
+	"pg_tui/store"
+)
+
+// AppState holds everything needed to browse and query a single Postgres
+// connection. Each open tab owns one AppState, so schema/table/column/result
+// state never leaks between connections.
+type AppState struct {
+	app              *tview.Application
+	pool             *pgxpool.Pool
+	schemaList       *tview.List
+	relationTree     *tview.TreeView
+	columnTable      *tview.Table
+	resultTable      *tview.Table
+	planTree         *tview.TreeView
+	planDetail       *tview.TextView
+	savedQueriesList *tview.List
+	queries          *store.QueryStore
+	queryArea        *tview.TextArea
+	statusBar        *tview.TextView
+	layout           *tview.Flex
+	pages            *tview.Pages
+	resultStream     *ResultStream
+	lastQuery        string
+	previewLimit     int
+	currentSchema    string
+	currentTable     string
+	relationKind     catalog.RelationKind
+	detailMode       detailMode
+	currentFKs       []catalog.ForeignKey
+
+	history      *history.History
+	historyIdx   int
+	historyDraft string
+
+	name  string
+	tabID string
+}
+
+// App owns the set of open connection tabs and the keybindings that operate
+// across them (as opposed to AppState's keybindings, which operate within a
+// single tab). Tabs are rendered as a tview.Pages with a header above it
+// showing tab names, so the user can compare e.g. a staging and production
+// database side by side without restarting.
+type App struct {
+	app          *tview.Application
+	pages        *tview.Pages
+	header       *tview.TextView
+	root         *tview.Flex
+	previewLimit int
+
+	tabs   []*AppState
+	active int
+	nextID int
+
+	history *history.History
+	queries *store.QueryStore
+}
+
+func newApp(app *tview.Application, previewLimit int) *App {
+	h, err := history.Open()
+	if err != nil {
+		h = history.NewMemory()
+	}
+	qs, err := store.Open()
+	if err != nil {
+		qs = store.NewMemory()
+	}
+	return &App{app: app, previewLimit: previewLimit, history: h, queries: qs}
+}
+
+func (a *App) initUI() {
+	a.header = tview.NewTextView().SetDynamicColors(true)
+	a.pages = tview.NewPages()
+	a.updateHeader()
+
+	a.root = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.header, 1, 0, false).
+		AddItem(a.pages, 0, 1, true)
+
+	a.app.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyCtrlN:
+			a.showConnectModal()
+			return nil
+		case tcell.KeyCtrlW:
+			a.closeActiveTab()
+			return nil
+		case tcell.KeyPgUp:
+			if ev.Modifiers()&tcell.ModCtrl != 0 {
+				a.switchTab(-1)
+				return nil
+			}
+		case tcell.KeyPgDn:
+			if ev.Modifiers()&tcell.ModCtrl != 0 {
+				a.switchTab(1)
+				return nil
+			}
+		}
+		// While the connection form is open, typed keys belong to it rather
+		// than to the tab underneath; Ctrl-C still reaches the tab.
+		if name, _ := a.pages.GetFrontPage(); name == "connect" && ev.Key() != tcell.KeyCtrlC {
+			return ev
+		}
+		if t := a.activeTab(); t != nil {
+			return t.handleKey(ev)
+		}
+		return ev
+	})
+
+	a.app.SetRoot(a.root, true)
+}
+
+func (a *App) activeTab() *AppState {
+	if a.active < 0 || a.active >= len(a.tabs) {
+		return nil
+	}
+	return a.tabs[a.active]
+}
+
+// openConnection opens a pool against url, adds it as a new tab named name,
+// and switches to it.
+func (a *App) openConnection(name, url string) error {
+	ctx := context.Background()
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	cfg.MaxConns = 5
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	a.nextID++
+	state := &AppState{
+		app:          a.app,
+		pool:         pool,
+		previewLimit: a.previewLimit,
+		queries:      a.queries,
+		history:      a.history,
+		historyIdx:   -1,
+		name:         name,
+		tabID:        fmt.Sprintf("tab-%d", a.nextID),
+	}
+	state.initUI()
+
+	a.tabs = append(a.tabs, state)
+	a.pages.AddPage(state.tabID, state.pages, true, false)
+	a.switchToIndex(len(a.tabs) - 1)
+
+	if err := state.loadSchemas(); err != nil {
+		state.toast("Failed to load schemas: %v", err)
+	}
+	state.updateStatus("F5: Run | F6: Explain | Ctrl-S: Save | Ctrl-D: Delete | Ctrl-E: Export | Ctrl-R: History | i: Indexes/FKs | g: Goto FK | q: Quit | r: Refresh | Tab: Cycle Focus")
+	return nil
+}
+
+func (a *App) closeActiveTab() {
+	t := a.activeTab()
+	if t == nil {
+		return
+	}
+	t.closeResultStream()
+	t.pool.Close()
+	a.pages.RemovePage(t.tabID)
+	a.tabs = append(a.tabs[:a.active], a.tabs[a.active+1:]...)
+
+	if len(a.tabs) == 0 {
+		a.active = 0
+		a.updateHeader()
+		a.showConnectModal()
+		return
+	}
+	if a.active >= len(a.tabs) {
+		a.active = len(a.tabs) - 1
+	}
+	a.switchToIndex(a.active)
+}
+
+func (a *App) switchTab(delta int) {
+	if len(a.tabs) == 0 {
+		return
+	}
+	next := (a.active + delta + len(a.tabs)) % len(a.tabs)
+	a.switchToIndex(next)
+}
+
+func (a *App) switchToIndex(i int) {
+	a.active = i
+	a.pages.SwitchToPage(a.tabs[i].tabID)
+	a.app.SetFocus(a.tabs[i].schemaList)
+	a.updateHeader()
+}
+
+func (a *App) updateHeader() {
+	if len(a.tabs) == 0 {
+		a.header.SetText(" [yellow]No connections — Ctrl-N to add one[-]")
+		return
+	}
+	var b strings.Builder
+	b.WriteString(" ")
+	for i, t := range a.tabs {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		if i == a.active {
+			fmt.Fprintf(&b, "[black:white] %s [-:-]", t.name)
+		} else {
+			fmt.Fprintf(&b, " %s ", t.name)
+		}
+	}
+	b.WriteString("   (Ctrl-N: New | Ctrl-W: Close | Ctrl-PgUp/PgDn: Switch)")
+	a.header.SetText(b.String())
+}
+
+// showConnectModal prompts for a display name and connection URL and opens
+// it as a new tab on submit.
+func (a *App) showConnectModal() {
+	form := tview.NewForm()
+	form.AddInputField("Name", "", 24, nil, nil)
+	form.AddInputField("URL", "", 60, nil, nil)
+	form.SetBorder(true).SetTitle(" New Connection (Ctrl-N) ")
+
+	form.AddButton("Connect", func() {
+		name := form.GetFormItem(0).(*tview.InputField).GetText()
+		url := form.GetFormItem(1).(*tview.InputField).GetText()
+		if name == "" || url == "" {
+			return
+		}
+		a.hideModal()
+		if err := a.openConnection(name, url); err != nil {
+			a.header.SetText(fmt.Sprintf(" [red]connect failed: %v[-]", err))
+		}
+	})
+	form.AddButton("Cancel", func() {
+		a.hideModal()
+	})
+
+	a.showModal(form, 70, 9)
+}
+
+// showModal centers p over the tab area as a fixed-size overlay page.
+func (a *App) showModal(p tview.Primitive, width, height int) {
+	modal := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(p, width, 0, true).
+			AddItem(nil, 0, 1, false), height, 0, true).
+		AddItem(nil, 0, 1, false)
+	a.pages.AddPage("connect", modal, true, true)
+	a.app.SetFocus(p)
+}
+
+func (a *App) hideModal() {
+	a.pages.RemovePage("connect")
+	if t := a.activeTab(); t != nil {
+		a.app.SetFocus(t.queryArea)
+	}
+}
@@ -0,0 +1,179 @@
+// Package catalog centralises the information_schema/pg_catalog queries the
+// schema browser needs, so the rest of the app can ask for "the relations in
+// this schema" or "the foreign keys on this table" without embedding SQL.
+package catalog
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RelationKind identifies what sort of object a catalog entry is, so the
+// browser can group and render them differently.
+type RelationKind string
+
+const (
+	Table            RelationKind = "table"
+	View             RelationKind = "view"
+	MaterializedView RelationKind = "materialized view"
+	Sequence         RelationKind = "sequence"
+	Function         RelationKind = "function"
+)
+
+// Relation is one browsable object within a schema.
+type Relation struct {
+	Schema string
+	Name   string
+	Kind   RelationKind
+}
+
+// Index describes one index on a table, as reported by pg_indexes.
+type Index struct {
+	Name       string
+	Definition string
+}
+
+// ForeignKey describes one outgoing foreign key from a table's column(s) to
+// another table's column(s).
+type ForeignKey struct {
+	ConstraintName string
+	Column         string
+	RefSchema      string
+	RefTable       string
+	RefColumn      string
+	UpdateRule     string
+	DeleteRule     string
+}
+
+// CheckConstraint describes one CHECK constraint on a table.
+type CheckConstraint struct {
+	ConstraintName string
+	Clause         string
+}
+
+// ListRelations returns the base tables, views, materialized views,
+// sequences, and functions defined in schema, ordered by kind then name.
+func ListRelations(ctx context.Context, pool *pgxpool.Pool, schema string) ([]Relation, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT table_name,
+		       CASE table_type
+		           WHEN 'BASE TABLE' THEN 'table'
+		           WHEN 'VIEW' THEN 'view'
+		           ELSE 'table'
+		       END AS kind
+		FROM information_schema.tables
+		WHERE table_schema = $1
+		UNION ALL
+		SELECT matviewname, 'materialized view'
+		FROM pg_matviews
+		WHERE schemaname = $1
+		UNION ALL
+		SELECT sequence_name, 'sequence'
+		FROM information_schema.sequences
+		WHERE sequence_schema = $1
+		UNION ALL
+		SELECT routine_name, 'function'
+		FROM information_schema.routines
+		WHERE routine_schema = $1 AND routine_type = 'FUNCTION'
+		ORDER BY kind, table_name`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Relation
+	for rows.Next() {
+		var name, kind string
+		if err := rows.Scan(&name, &kind); err != nil {
+			return nil, err
+		}
+		out = append(out, Relation{Schema: schema, Name: name, Kind: RelationKind(kind)})
+	}
+	return out, rows.Err()
+}
+
+// ListIndexes returns the indexes defined on schema.table.
+func ListIndexes(ctx context.Context, pool *pgxpool.Pool, schema, table string) ([]Index, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT indexname, indexdef
+		FROM pg_indexes
+		WHERE schemaname = $1 AND tablename = $2
+		ORDER BY indexname`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Index
+	for rows.Next() {
+		var idx Index
+		if err := rows.Scan(&idx.Name, &idx.Definition); err != nil {
+			return nil, err
+		}
+		out = append(out, idx)
+	}
+	return out, rows.Err()
+}
+
+// ListForeignKeys returns the outgoing foreign keys defined on schema.table.
+func ListForeignKeys(ctx context.Context, pool *pgxpool.Pool, schema, table string) ([]ForeignKey, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			kcu.constraint_name,
+			kcu.column_name,
+			ccu.table_schema,
+			ccu.table_name,
+			ccu.column_name,
+			rc.update_rule,
+			rc.delete_rule
+		FROM information_schema.key_column_usage kcu
+		JOIN information_schema.referential_constraints rc
+			ON rc.constraint_name = kcu.constraint_name
+			AND rc.constraint_schema = kcu.constraint_schema
+		JOIN information_schema.constraint_column_usage ccu
+			ON ccu.constraint_name = rc.unique_constraint_name
+			AND ccu.constraint_schema = rc.unique_constraint_schema
+		WHERE kcu.table_schema = $1 AND kcu.table_name = $2
+		ORDER BY kcu.constraint_name, kcu.ordinal_position`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ForeignKey
+	for rows.Next() {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.ConstraintName, &fk.Column, &fk.RefSchema, &fk.RefTable, &fk.RefColumn, &fk.UpdateRule, &fk.DeleteRule); err != nil {
+			return nil, err
+		}
+		out = append(out, fk)
+	}
+	return out, rows.Err()
+}
+
+// ListCheckConstraints returns the CHECK constraints defined on schema.table.
+func ListCheckConstraints(ctx context.Context, pool *pgxpool.Pool, schema, table string) ([]CheckConstraint, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT cc.constraint_name, cc.check_clause
+		FROM information_schema.check_constraints cc
+		JOIN information_schema.table_constraints tc
+			ON tc.constraint_name = cc.constraint_name
+			AND tc.constraint_schema = cc.constraint_schema
+		WHERE tc.table_schema = $1 AND tc.table_name = $2
+		ORDER BY cc.constraint_name`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CheckConstraint
+	for rows.Next() {
+		var c CheckConstraint
+		if err := rows.Scan(&c.ConstraintName, &c.Clause); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
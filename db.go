@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
@@ -9,7 +12,10 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rivo/tview"
+
+	"pg_tui/history"
 )
 
 func (s *AppState) loadSchemas() error {
@@ -40,80 +46,16 @@ func (s *AppState) loadSchemas() error {
 	}
 	if len(schemas) > 0 {
 		s.currentSchema = schemas[0]
-		s.loadTables(s.currentSchema)
+		s.loadRelations(s.currentSchema)
+		s.refreshSavedQueriesList()
 	}
 	return rows.Err()
 }
 
-func (s *AppState) loadTables(schema string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	rows, err := s.pool.Query(ctx, `
-		SELECT table_name
-		FROM information_schema.tables
-		WHERE table_schema = $1 AND table_type = 'BASE TABLE'
-		ORDER BY table_name`, schema)
-	if err != nil {
-		s.toast("load tables: %v", err)
-		return
-	}
-	defer rows.Close()
-
-	s.tableList.Clear()
-	tables := []string{}
-	for rows.Next() {
-		var n string
-		if err := rows.Scan(&n); err != nil {
-			s.toast("scan: %v", err)
-			return
-		}
-		tables = append(tables, n)
-	}
-	for i, t := range tables {
-		s.tableList.AddItem(t, "", rune('a'+(i%26)), nil)
-	}
-	if len(tables) > 0 {
-		s.currentTable = tables[0]
-		s.loadColumns(schema, tables[0])
-		s.previewTable(schema, tables[0])
-	} else {
-		s.columnTable.Clear()
-		s.resultTable.Clear()
-	}
-}
-
-func (s *AppState) loadColumns(schema, table string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	rows, err := s.pool.Query(ctx, `
-		SELECT column_name, data_type, is_nullable
-		FROM information_schema.columns
-		WHERE table_schema = $1 AND table_name = $2
-		ORDER BY ordinal_position`, schema, table)
-	if err != nil {
-		s.toast("load columns: %v", err)
-		return
-	}
-	defer rows.Close()
-
-	s.columnTable.Clear()
-	setHeader(s.columnTable, []string{"Column", "Type", "Nullable"})
-	row := 1
-	for rows.Next() {
-		var name, typ, nullable string
-		if err := rows.Scan(&name, &typ, &nullable); err != nil {
-			s.toast("scan: %v", err)
-			return
-		}
-		setRow(s.columnTable, row, []string{name, typ, nullable})
-		row++
-	}
-}
-
 func (s *AppState) previewTable(schema, table string) {
 	ident := pgIdent(schema) + "." + pgIdent(table)
 	q := fmt.Sprintf("SELECT * FROM %s LIMIT %d", ident, s.previewLimit)
-	s.runQueryInto(q, s.resultTable)
+	s.streamQueryInto(q, s.resultTable, nil)
 }
 
 func (s *AppState) runAdhocQuery(q string) {
@@ -126,52 +68,29 @@ func (s *AppState) runAdhocQuery(q string) {
 		s.toast("Multiple statements detected; please run one at a time.")
 		return
 	}
-	s.runQueryInto(q, s.resultTable)
+	s.historyIdx = -1
+	s.historyDraft = ""
+	s.streamQueryInto(q, s.resultTable, func(rows int, dur time.Duration, err error) {
+		s.recordHistory(q, dur, rows, err)
+	})
 }
 
-func (s *AppState) runQueryInto(q string, tbl *tview.Table) {
-	started := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	rows, err := s.pool.Query(ctx, q)
-	if err != nil {
-		s.toast("query error: %v", err)
+// recordHistory appends an entry for a just-finished ad-hoc query to this
+// tab's shared history log, ignoring write failures (history is best-effort).
+func (s *AppState) recordHistory(query string, dur time.Duration, rows int, err error) {
+	if s.history == nil {
 		return
 	}
-	defer rows.Close()
-
-	flds := rows.FieldDescriptions()
-	headers := make([]string, len(flds))
-	for i, f := range flds {
-		headers[i] = string(f.Name)
-	}
-	tbl.Clear()
-	setHeader(tbl, headers)
-	row := 1
-	for rows.Next() {
-		vals, err := rows.Values()
-		if err != nil {
-			s.toast("row error: %v", err)
-			return
-		}
-		cells := make([]string, len(vals))
-		for i, v := range vals {
-			if v == nil {
-				cells[i] = "NULL"
-				continue
-			}
-			cells[i] = fmt.Sprint(v)
-		}
-		setRow(tbl, row, cells)
-		row++
+	e := history.Entry{
+		Query:     query,
+		Timestamp: time.Now(),
+		Duration:  dur,
+		Rows:      rows,
 	}
-	if err := rows.Err(); err != nil {
-		s.toast("rows err: %v", err)
-		return
+	if err != nil {
+		e.Err = err.Error()
 	}
-	s.resultTable.ScrollToBeginning()
-	s.toast("%d rows in %s", row-1, time.Since(started).Truncate(time.Millisecond))
+	_ = s.history.Append(e)
 }
 
 func setHeader(t *tview.Table, cols []string) {
@@ -212,3 +131,58 @@ func pgIdent(name string) string {
 	}
 	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
+
+// pgLiteral renders a value scanned from pgx as a SQL literal suitable for
+// an INSERT statement, picking the escaping rule for its Go type. This has
+// to cover every type pgx's default codecs decode a driver value into, not
+// just the common scalars: NUMERIC/DECIMAL comes back as pgtype.Numeric,
+// UUID as [16]byte, and JSON/JSONB as map[string]any or []any, none of
+// which render correctly through a bare fmt.Sprint.
+func pgLiteral(v any) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case []byte:
+		return `'\x` + hex.EncodeToString(val) + "'"
+	case [16]byte:
+		return "'" + formatUUID(val) + "'"
+	case time.Time:
+		return "'" + val.Format("2006-01-02 15:04:05.999999Z07:00") + "'"
+	case int, int16, int32, int64, float32, float64:
+		return fmt.Sprint(val)
+	case pgtype.Numeric:
+		dv, err := val.Value()
+		if err != nil || dv == nil {
+			return "NULL"
+		}
+		return fmt.Sprint(dv)
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "NULL"
+		}
+		return "'" + strings.ReplaceAll(string(data), "'", "''") + "'"
+	case driver.Valuer:
+		// Other pgtype values (interval, time, ...) render from the text
+		// form their Value method returns.
+		dv, err := val.Value()
+		if err != nil || dv == nil {
+			return "NULL"
+		}
+		return pgLiteral(dv)
+	default:
+		return "'" + strings.ReplaceAll(fmt.Sprint(val), "'", "''") + "'"
+	}
+}
+
+// formatUUID renders pgx's decoded [16]byte UUID representation in standard
+// 8-4-4-4-12 hex form.
+func formatUUID(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,141 @@
+// Package export streams query results out to disk in one of a handful of
+// formats. Each format is an independent RowWriter so new ones can be added
+// without touching the others.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format identifies an output format for exported rows.
+type Format string
+
+const (
+	CSV       Format = "csv"
+	TSV       Format = "tsv"
+	JSONLines Format = "json-lines"
+	SQLInsert Format = "sql-insert"
+)
+
+// RowWriter streams a header followed by rows to an output format.
+// Implementations own their own buffering; Close flushes it.
+type RowWriter interface {
+	WriteHeader(cols []string) error
+	WriteRow(vals []any) error
+	Close() error
+}
+
+// Options carries format-specific configuration. Only SQLInsert needs a
+// table name and functions to quote identifiers/literals the way the
+// target database expects.
+type Options struct {
+	Table        string
+	QuoteIdent   func(string) string
+	QuoteLiteral func(any) string
+}
+
+// NewRowWriter returns a RowWriter for format, writing to w.
+func NewRowWriter(format Format, w io.Writer, opts Options) (RowWriter, error) {
+	switch format {
+	case CSV:
+		return &delimitedWriter{cw: csv.NewWriter(w)}, nil
+	case TSV:
+		cw := csv.NewWriter(w)
+		cw.Comma = '\t'
+		return &delimitedWriter{cw: cw}, nil
+	case JSONLines:
+		return &jsonLinesWriter{w: bufio.NewWriter(w)}, nil
+	case SQLInsert:
+		if opts.Table == "" || opts.QuoteIdent == nil || opts.QuoteLiteral == nil {
+			return nil, fmt.Errorf("sql-insert export requires a table name and quoting functions")
+		}
+		return &sqlInsertWriter{w: bufio.NewWriter(w), opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+type delimitedWriter struct {
+	cw *csv.Writer
+}
+
+func (d *delimitedWriter) WriteHeader(cols []string) error { return d.cw.Write(cols) }
+
+func (d *delimitedWriter) WriteRow(vals []any) error {
+	cells := make([]string, len(vals))
+	for i, v := range vals {
+		if v == nil {
+			continue
+		}
+		cells[i] = fmt.Sprint(v)
+	}
+	return d.cw.Write(cells)
+}
+
+func (d *delimitedWriter) Close() error {
+	d.cw.Flush()
+	return d.cw.Error()
+}
+
+type jsonLinesWriter struct {
+	w       *bufio.Writer
+	headers []string
+}
+
+func (j *jsonLinesWriter) WriteHeader(cols []string) error {
+	j.headers = cols
+	return nil
+}
+
+func (j *jsonLinesWriter) WriteRow(vals []any) error {
+	obj := make(map[string]any, len(vals))
+	for i, v := range vals {
+		name := fmt.Sprintf("col%d", i)
+		if i < len(j.headers) {
+			name = j.headers[i]
+		}
+		obj[name] = v
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonLinesWriter) Close() error { return j.w.Flush() }
+
+type sqlInsertWriter struct {
+	w       *bufio.Writer
+	opts    Options
+	headers []string
+}
+
+func (s *sqlInsertWriter) WriteHeader(cols []string) error {
+	s.headers = cols
+	return nil
+}
+
+func (s *sqlInsertWriter) WriteRow(vals []any) error {
+	cols := make([]string, len(s.headers))
+	for i, h := range s.headers {
+		cols[i] = s.opts.QuoteIdent(h)
+	}
+	literals := make([]string, len(vals))
+	for i, v := range vals {
+		literals[i] = s.opts.QuoteLiteral(v)
+	}
+	_, err := fmt.Fprintf(s.w, "INSERT INTO %s (%s) VALUES (%s);\n",
+		s.opts.Table, strings.Join(cols, ", "), strings.Join(literals, ", "))
+	return err
+}
+
+func (s *sqlInsertWriter) Close() error { return s.w.Flush() }
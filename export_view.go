@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"pg_tui/export"
+)
+
+// showExportModal prompts for an export format and destination path, then
+// re-runs the query currently shown in the result table and streams its
+// rows to disk in that format.
+func (s *AppState) showExportModal() {
+	formats := []string{string(export.CSV), string(export.TSV), string(export.JSONLines), string(export.SQLInsert)}
+
+	form := tview.NewForm()
+	form.AddDropDown("Format", formats, 0, nil)
+	form.AddInputField("Path", "", 50, nil, nil)
+	form.SetBorder(true).SetTitle(" Export Results (Ctrl-E) ")
+
+	form.AddButton("Export", func() {
+		_, format := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		path := form.GetFormItem(1).(*tview.InputField).GetText()
+		if path == "" {
+			return
+		}
+		s.hideModal()
+		s.runExport(export.Format(format), path)
+	})
+	form.AddButton("Cancel", func() {
+		s.hideModal()
+	})
+
+	s.showModal(form, 70, 9)
+}
+
+// runExport re-executes the last query run in this tab and streams its
+// rows to path in format, reporting progress and the final row count
+// through toast.
+func (s *AppState) runExport(format export.Format, path string) {
+	q := strings.TrimSpace(s.lastQuery)
+	if q == "" {
+		s.toast("No query to export; run one first.")
+		return
+	}
+
+	s.updateStatus("Exporting…")
+	go func() {
+		n, err := s.exportToFile(format, path, q)
+		s.app.QueueUpdateDraw(func() {
+			if err != nil {
+				s.toast("export error: %v", err)
+				return
+			}
+			s.toast("Exported %d rows to %s", n, path)
+		})
+	}()
+}
+
+func (s *AppState) exportToFile(format export.Format, path, q string) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var opts export.Options
+	if format == export.SQLInsert {
+		opts.Table = s.exportTableName()
+		opts.QuoteIdent = pgIdent
+		opts.QuoteLiteral = pgLiteral
+	}
+
+	rw, err := export.NewRowWriter(format, f, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	flds := rows.FieldDescriptions()
+	headers := make([]string, len(flds))
+	for i, fd := range flds {
+		headers[i] = string(fd.Name)
+	}
+	if err := rw.WriteHeader(headers); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return n, err
+		}
+		if err := rw.WriteRow(vals); err != nil {
+			return n, err
+		}
+		n++
+		if n%10000 == 0 {
+			written := n
+			s.app.QueueUpdateDraw(func() { s.toast("Exporting… %d rows written", written) })
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	return n, rw.Close()
+}
+
+// exportTableName picks the INSERT INTO target for a sql-insert export:
+// the currently selected table if there is one, otherwise a generic name.
+func (s *AppState) exportTableName() string {
+	if s.currentTable == "" {
+		return "exported_rows"
+	}
+	if s.currentSchema == "" {
+		return pgIdent(s.currentTable)
+	}
+	return pgIdent(s.currentSchema) + "." + pgIdent(s.currentTable)
+}
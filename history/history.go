@@ -0,0 +1,111 @@
+// Package history persists a rolling log of executed ad-hoc queries to
+// $XDG_CONFIG_HOME/dbtui/history.json so they survive restarts and can be
+// searched back through later.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxEntries bounds the history to a rolling window of the most recent
+// queries; older entries are dropped as new ones are appended.
+const maxEntries = 1000
+
+// Entry records one executed ad-hoc query.
+type Entry struct {
+	Query     string        `json:"query"`
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration_ns"`
+	Rows      int           `json:"rows"`
+	Err       string        `json:"error,omitempty"`
+}
+
+// History is a thread-safe, file-backed ring buffer of query history.
+type History struct {
+	mu      sync.Mutex
+	path    string
+	entries []Entry
+}
+
+// Open loads history from $XDG_CONFIG_HOME/dbtui/history.json, falling back
+// to ~/.config if XDG_CONFIG_HOME is unset. A missing file is not an error;
+// it just starts empty.
+func Open() (*History, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	h := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// NewMemory returns an in-memory-only history, used when the on-disk log
+// couldn't be opened so the rest of the UI doesn't have to special-case a
+// nil history.
+func NewMemory() *History {
+	return &History{}
+}
+
+func configPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dbtui", "history.json"), nil
+}
+
+// Append records e, trimming the log to the most recent maxEntries.
+func (h *History) Append(e Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, e)
+	if len(h.entries) > maxEntries {
+		h.entries = h.entries[len(h.entries)-maxEntries:]
+	}
+	return h.persistLocked()
+}
+
+// All returns every entry, oldest first.
+func (h *History) All() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]Entry, len(h.entries))
+	copy(out, h.entries)
+	return out
+}
+
+func (h *History) persistLocked() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0o644)
+}
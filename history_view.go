@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// atFirstLine reports whether the query editor's cursor is on its first
+// line, the point from which Up should start walking back through history.
+func (s *AppState) atFirstLine() bool {
+	row, _, _, _ := s.queryArea.GetCursor()
+	return row == 0
+}
+
+// atLastLine reports whether the cursor is on the editor's last line, the
+// point from which Down should walk forward through history.
+func (s *AppState) atLastLine() bool {
+	row, _, _, _ := s.queryArea.GetCursor()
+	return row == strings.Count(s.queryArea.GetText(), "\n")
+}
+
+// historyUp recalls the previous query, remembering whatever was being
+// typed so Down can get back to it.
+func (s *AppState) historyUp() {
+	if s.history == nil {
+		return
+	}
+	entries := s.history.All()
+	if len(entries) == 0 {
+		return
+	}
+	if s.historyIdx < 0 {
+		s.historyDraft = s.queryArea.GetText()
+		s.historyIdx = len(entries)
+	}
+	if s.historyIdx == 0 {
+		return
+	}
+	s.historyIdx--
+	s.queryArea.SetText(entries[s.historyIdx].Query, true)
+}
+
+// historyDown walks forward through history, restoring the in-progress
+// draft once the user steps past the most recent entry.
+func (s *AppState) historyDown() {
+	if s.history == nil || s.historyIdx < 0 {
+		return
+	}
+	entries := s.history.All()
+	s.historyIdx++
+	if s.historyIdx >= len(entries) {
+		s.historyIdx = -1
+		s.queryArea.SetText(s.historyDraft, true)
+		return
+	}
+	s.queryArea.SetText(entries[s.historyIdx].Query, true)
+}
+
+// showHistorySearch opens a Ctrl-R overlay that reverse-searches query
+// history by substring, mirroring a shell's incremental search: typing
+// narrows the matches, Ctrl-R cycles to the next older match, Enter loads
+// the selected query into the editor, Esc cancels.
+func (s *AppState) showHistorySearch() {
+	if s.history == nil {
+		return
+	}
+	entries := s.history.All()
+
+	input := tview.NewInputField().SetLabel("(reverse-i-search): ")
+	preview := tview.NewTextView().SetDynamicColors(true)
+	preview.SetBorder(true).SetTitle(" Match ")
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(preview, 3, 0, false)
+	box.SetBorder(true).SetTitle(" Search History (Ctrl-R: older match, Enter: use, Esc: cancel) ")
+
+	matchIdx := -1
+	render := func(term string) {
+		matchIdx = -1
+		if term == "" {
+			preview.SetText("")
+			return
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if strings.Contains(entries[i].Query, term) {
+				matchIdx = i
+				break
+			}
+		}
+		if matchIdx < 0 {
+			preview.SetText("[red]no match[-]")
+			return
+		}
+		preview.SetText(entries[matchIdx].Query)
+	}
+
+	cycle := func(term string) {
+		if matchIdx < 0 {
+			return
+		}
+		for i := matchIdx - 1; i >= 0; i-- {
+			if strings.Contains(entries[i].Query, term) {
+				matchIdx = i
+				preview.SetText(entries[matchIdx].Query)
+				return
+			}
+		}
+		preview.SetText(fmt.Sprintf("[red]no earlier match[-]\n%s", entries[matchIdx].Query))
+	}
+
+	input.SetChangedFunc(func(text string) { render(text) })
+	input.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyCtrlR:
+			cycle(input.GetText())
+			return nil
+		case tcell.KeyEnter:
+			s.hideModal()
+			if matchIdx >= 0 {
+				s.historyIdx = -1
+				s.queryArea.SetText(entries[matchIdx].Query, true)
+			}
+			return nil
+		case tcell.KeyEsc:
+			s.hideModal()
+			return nil
+		}
+		return ev
+	})
+
+	s.showModal(box, 70, 6)
+}
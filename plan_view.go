@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// PlanNode mirrors one node of a Postgres `EXPLAIN (FORMAT JSON)` tree.
+// Field names match the JSON keys Postgres emits, so json.Unmarshal needs
+// no manual recursion beyond following the nested "Plans" array.
+type PlanNode struct {
+	NodeType     string      `json:"Node Type"`
+	RelationName string      `json:"Relation Name,omitempty"`
+	Alias        string      `json:"Alias,omitempty"`
+	PlanRows     float64     `json:"Plan Rows"`
+	ActualRows   float64     `json:"Actual Rows"`
+	ActualLoops  float64     `json:"Actual Loops"`
+	ActualTime   float64     `json:"Actual Total Time"`
+	SharedHit    float64     `json:"Shared Hit Blocks"`
+	SharedRead   float64     `json:"Shared Read Blocks"`
+	Children     []*PlanNode `json:"Plans,omitempty"`
+}
+
+// misestimated reports whether the planner's row estimate is off from the
+// observed row count by more than 10x in either direction.
+func (n *PlanNode) misestimated() bool {
+	if n.PlanRows <= 0 {
+		return n.ActualRows > 0
+	}
+	ratio := n.ActualRows / n.PlanRows
+	if ratio < 1 {
+		if ratio == 0 {
+			return n.ActualRows != n.PlanRows
+		}
+		ratio = 1 / ratio
+	}
+	return ratio > 10
+}
+
+func (n *PlanNode) label() string {
+	label := n.NodeType
+	if n.RelationName != "" {
+		label += " on " + n.RelationName
+	}
+	if n.Alias != "" && n.Alias != n.RelationName {
+		label += " " + n.Alias
+	}
+	return label
+}
+
+func (n *PlanNode) detail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Node:[-] %s\n", n.label())
+	fmt.Fprintf(&b, "[yellow]Estimated rows:[-] %.0f\n", n.PlanRows)
+	fmt.Fprintf(&b, "[yellow]Actual rows:[-] %.0f\n", n.ActualRows)
+	if n.misestimated() {
+		b.WriteString("[red]Row estimate is off by more than 10x[-]\n")
+	}
+	fmt.Fprintf(&b, "[yellow]Actual time:[-] %.3f ms\n", n.ActualTime)
+	fmt.Fprintf(&b, "[yellow]Loops:[-] %.0f\n", n.ActualLoops)
+	fmt.Fprintf(&b, "[yellow]Shared buffers:[-] hit=%.0f read=%.0f\n", n.SharedHit, n.SharedRead)
+	return b.String()
+}
+
+func (s *AppState) initPlanView() {
+	s.planTree = tview.NewTreeView()
+	s.planTree.SetBorder(true).SetTitle(" Query Plan (F6) ")
+
+	s.planDetail = tview.NewTextView().SetDynamicColors(true)
+	s.planDetail.SetBorder(true).SetTitle(" Plan Node Detail ")
+
+	s.planTree.SetChangedFunc(func(node *tview.TreeNode) {
+		pn, ok := node.GetReference().(*PlanNode)
+		if !ok {
+			s.planDetail.SetText("")
+			return
+		}
+		s.planDetail.SetText(pn.detail())
+	})
+}
+
+// runExplainPlan runs the query currently in queryArea through
+// EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) and renders the resulting plan
+// as a tree in planTree, with per-node stats in planDetail.
+func (s *AppState) runExplainPlan(q string) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return
+	}
+	if hasMultipleStatements(q) {
+		s.toast("Multiple statements detected; please run one at a time.")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var raw []byte
+	err := s.pool.QueryRow(ctx, "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) "+q).Scan(&raw)
+	if err != nil {
+		s.toast("explain error: %v", err)
+		return
+	}
+
+	var plans []struct {
+		Plan *PlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		s.toast("explain parse error: %v", err)
+		return
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		s.toast("explain returned no plan")
+		return
+	}
+
+	root := plans[0].Plan
+	treeRoot := buildPlanTreeNode(root)
+	treeRoot.SetExpanded(true)
+	s.planTree.SetRoot(treeRoot).SetCurrentNode(treeRoot)
+	s.planDetail.SetText(root.detail())
+	s.app.SetFocus(s.planTree)
+	s.toast("Plan loaded: %s", root.label())
+}
+
+func buildPlanTreeNode(n *PlanNode) *tview.TreeNode {
+	node := tview.NewTreeNode(n.label()).SetReference(n).SetSelectable(true).SetExpanded(true)
+	if n.misestimated() {
+		node.SetColor(tcell.ColorRed)
+	}
+	for _, child := range n.Children {
+		node.AddChild(buildPlanTreeNode(child))
+	}
+	return node
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rivo/tview"
+)
+
+var errQueryCanceled = errors.New("canceled by user")
+
+const (
+	resultStreamPageSize       = 500
+	resultStreamPrefetchMargin = 100
+)
+
+// ResultStream owns a server-side cursor over a running query and streams
+// rows into a tview.Table in pages, fetching ahead as the user scrolls near
+// the end of what's buffered instead of loading the whole result set into
+// memory (and the table widget) up front.
+type ResultStream struct {
+	app    *tview.Application
+	table  *tview.Table
+	tx     pgx.Tx
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// owner is the tab this stream was started from. Error reporting uses it
+	// even when fetchMore was triggered by a scroll prefetch (s == nil),
+	// since there's otherwise no AppState to toast through.
+	owner *AppState
+
+	cursor   string
+	pageSize int
+	started  time.Time
+
+	content *resultWindow
+
+	onDone   func(rows int, dur time.Duration, err error)
+	doneOnce sync.Once
+
+	mu         sync.Mutex
+	headersSet bool
+	fetched    int
+	fetching   bool
+	exhausted  bool
+	canceled   bool
+}
+
+// finish invokes onDone exactly once, however the stream ends (exhausted,
+// canceled, or errored).
+func (rs *ResultStream) finish(rows int, err error) {
+	rs.doneOnce.Do(func() {
+		if rs.onDone != nil {
+			rs.onDone(rows, time.Since(rs.started), err)
+		}
+	})
+}
+
+// streamQueryInto runs q through a DECLARE CURSOR and streams its rows into
+// tbl, fetching further pages as the user scrolls near the buffered edge.
+// Any stream already running against tbl is torn down first. onDone, if
+// non-nil, is called exactly once when the stream finishes, is canceled, or
+// fails outright.
+func (s *AppState) streamQueryInto(q string, tbl *tview.Table, onDone func(rows int, dur time.Duration, err error)) {
+	s.lastQuery = q
+	s.closeResultStream()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.toast("query error: %v", err)
+		cancel()
+		if onDone != nil {
+			onDone(0, 0, err)
+		}
+		return
+	}
+	if _, err := tx.Exec(ctx, "DECLARE dbtui_cursor CURSOR FOR "+q); err != nil {
+		s.toast("query error: %v", err)
+		_ = tx.Rollback(ctx)
+		cancel()
+		if onDone != nil {
+			onDone(0, 0, err)
+		}
+		return
+	}
+
+	content := &resultWindow{}
+	tbl.SetContent(content)
+	rs := &ResultStream{
+		app:      s.app,
+		table:    tbl,
+		tx:       tx,
+		ctx:      ctx,
+		cancel:   cancel,
+		owner:    s,
+		cursor:   "dbtui_cursor",
+		pageSize: resultStreamPageSize,
+		started:  time.Now(),
+		content:  content,
+		onDone:   onDone,
+	}
+	s.resultStream = rs
+
+	tbl.SetSelectable(true, false)
+	tbl.SetSelectionChangedFunc(func(row, col int) {
+		rs.maybePrefetch(row)
+	})
+
+	s.updateStatus("0 rows fetched / streaming…")
+	go rs.fetchMore(s)
+}
+
+// closeResultStream rolls back and discards any stream attached to this tab,
+// finishing it as canceled if it had not already ended.
+func (s *AppState) closeResultStream() {
+	rs := s.resultStream
+	if rs == nil {
+		return
+	}
+	rs.cancel()
+	_ = rs.tx.Rollback(context.Background())
+	rs.mu.Lock()
+	fetched := rs.fetched
+	rs.mu.Unlock()
+	rs.finish(fetched, errQueryCanceled)
+	s.resultStream = nil
+}
+
+// cancelRunningQuery is bound to Ctrl-C: it stops the active stream's cursor
+// query without waiting for it to finish fetching.
+func (s *AppState) cancelRunningQuery() {
+	rs := s.resultStream
+	if rs == nil {
+		return
+	}
+	rs.mu.Lock()
+	alreadyDone := rs.exhausted || rs.canceled
+	rs.canceled = true
+	fetched := rs.fetched
+	rs.mu.Unlock()
+	if alreadyDone {
+		return
+	}
+	_ = rs.tx.Rollback(context.Background())
+	rs.cancel()
+	rs.finish(fetched, errQueryCanceled)
+	s.toast("Query canceled.")
+}
+
+// failStream ends the stream on a FETCH/iteration error: it marks the
+// stream exhausted, finishes it with err, and rolls back the cursor's
+// transaction, regardless of whether this fetchMore call came from the
+// original query (s != nil) or a scroll prefetch (s == nil) — otherwise a
+// prefetch failure would leave the status bar stuck mid-stream forever with
+// no toast and a dead cursor every further scroll retries.
+func (rs *ResultStream) failStream(err error) {
+	rs.mu.Lock()
+	rs.exhausted = true
+	fetched := rs.fetched
+	rs.mu.Unlock()
+
+	rs.finish(fetched, err)
+	_ = rs.tx.Rollback(context.Background())
+	rs.cancel()
+
+	rs.app.QueueUpdateDraw(func() {
+		if rs.owner == nil {
+			return
+		}
+		rs.owner.updateStatus("F5: Run | F6: Explain | Ctrl-S: Save | Ctrl-D: Delete | Ctrl-E: Export | Ctrl-R: History | i: Indexes/FKs | g: Goto FK | q: Quit | r: Refresh | Tab: Cycle Focus")
+		rs.owner.toast("stream error: %v", err)
+	})
+}
+
+// maybePrefetch fetches another page once the selection scrolls within
+// resultStreamPrefetchMargin rows of the end of what's buffered so far.
+func (rs *ResultStream) maybePrefetch(row int) {
+	rs.mu.Lock()
+	near := !rs.fetching && !rs.exhausted && !rs.canceled && row >= rs.fetched-resultStreamPrefetchMargin
+	rs.mu.Unlock()
+	if near {
+		go rs.fetchMore(nil)
+	}
+}
+
+// fetchMore pulls the next page through the cursor and appends it to the
+// table. s is nil when fetchMore is triggered by a scroll prefetch rather
+// than the query that started the stream; status/toast updates are then
+// best-effort against whatever tab still owns this stream.
+func (rs *ResultStream) fetchMore(s *AppState) {
+	rs.mu.Lock()
+	if rs.fetching || rs.exhausted || rs.canceled {
+		rs.mu.Unlock()
+		return
+	}
+	rs.fetching = true
+	rs.mu.Unlock()
+	defer func() {
+		rs.mu.Lock()
+		rs.fetching = false
+		rs.mu.Unlock()
+	}()
+
+	rows, err := rs.tx.Query(rs.ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", rs.pageSize, rs.cursor))
+	if err != nil {
+		rs.failStream(err)
+		return
+	}
+	defer rows.Close()
+
+	rs.mu.Lock()
+	firstFetch := !rs.headersSet
+	var headers []string
+	if firstFetch {
+		flds := rows.FieldDescriptions()
+		headers = make([]string, len(flds))
+		for i, f := range flds {
+			headers[i] = string(f.Name)
+		}
+		rs.headersSet = true
+	}
+	rs.mu.Unlock()
+
+	var batch [][]string
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			break
+		}
+		cells := make([]string, len(vals))
+		for i, v := range vals {
+			if v == nil {
+				cells[i] = "NULL"
+				continue
+			}
+			cells[i] = fmt.Sprint(v)
+		}
+		batch = append(batch, cells)
+	}
+	if err := rows.Err(); err != nil {
+		rs.failStream(err)
+		return
+	}
+	exhausted := len(batch) < rs.pageSize
+
+	rs.app.QueueUpdateDraw(func() {
+		if firstFetch {
+			rs.content.headers = headers
+		}
+		viewportRow, _ := rs.table.GetSelection()
+		rs.content.append(batch, viewportRow)
+
+		rs.mu.Lock()
+		rs.fetched += len(batch)
+		rs.exhausted = rs.exhausted || exhausted
+		fetched, done := rs.fetched, rs.exhausted
+		rs.mu.Unlock()
+
+		if done {
+			rs.finish(fetched, nil)
+			_ = rs.tx.Rollback(context.Background())
+			rs.cancel()
+		}
+		if s == nil {
+			// A scroll prefetch: report through the tab that started the
+			// stream, as long as this is still that tab's stream.
+			if rs.owner == nil || rs.owner.resultStream != rs {
+				return
+			}
+			s = rs.owner
+		}
+		if done {
+			s.updateStatus("F5: Run | F6: Explain | Ctrl-S: Save | Ctrl-D: Delete | Ctrl-E: Export | Ctrl-R: History | i: Indexes/FKs | g: Goto FK | q: Quit | r: Refresh | Tab: Cycle Focus")
+			s.toast("%d rows in %s", fetched, time.Since(rs.started).Truncate(time.Millisecond))
+		} else {
+			s.updateStatus(fmt.Sprintf("%d rows fetched / streaming… (Ctrl-C to cancel)", fetched))
+		}
+	})
+}
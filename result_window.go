@@ -0,0 +1,102 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// resultWindowSize bounds how many fetched rows resultWindow keeps in memory
+// at once. Rows fall out of the window as fetchMore reports where the user
+// is currently scrolled, so streaming a multi-million-row query never grows
+// the table's backing storage past a fixed bound.
+const resultWindowSize = resultStreamPageSize * 4
+
+// resultWindow is the tview.TableContent backing a streamed result set. It
+// reports the full row count fetched so far (so the scrollbar and selection
+// span the whole result), but only retains a window of rows around wherever
+// the viewport last was; everything else is evicted and rendered as a
+// placeholder cell rather than re-fetched or kept around. All methods are
+// only ever called from the UI goroutine, via QueueUpdateDraw, matching
+// tview's requirement that TableContent not be touched concurrently with
+// Table.Draw().
+type resultWindow struct {
+	tview.TableContentReadOnly
+
+	headers []string
+	start   int // index (0-based, header excluded) of rows[0] in the full result
+	rows    [][]string
+}
+
+// append adds a freshly-fetched batch of data rows, then evicts whatever now
+// falls more than resultWindowSize/2 rows behind viewportRow.
+func (w *resultWindow) append(batch [][]string, viewportRow int) {
+	w.rows = append(w.rows, batch...)
+
+	evictBefore := viewportRow - resultWindowSize/2
+	if evictBefore <= w.start {
+		return
+	}
+	drop := evictBefore - w.start
+	if drop > len(w.rows) {
+		drop = len(w.rows)
+	}
+	w.rows = w.rows[drop:]
+	w.start += drop
+}
+
+func (w *resultWindow) GetCell(row, column int) *tview.TableCell {
+	if row == 0 {
+		if column >= len(w.headers) {
+			return nil
+		}
+		return headerCell(w.headers[column], column)
+	}
+	idx := row - 1 - w.start
+	if idx < 0 || idx >= len(w.rows) || column >= len(w.rows[idx]) {
+		return placeholderCell(column)
+	}
+	return dataCell(w.rows[idx][column], column)
+}
+
+func (w *resultWindow) GetRowCount() int {
+	return w.start + len(w.rows) + 1 // +1 for the header row
+}
+
+func (w *resultWindow) GetColumnCount() int {
+	return len(w.headers)
+}
+
+func (w *resultWindow) Clear() {
+	w.headers = nil
+	w.rows = nil
+	w.start = 0
+}
+
+func headerCell(text string, col int) *tview.TableCell {
+	if col > 0 {
+		text = " | " + text
+	}
+	text = " " + text + " "
+	return tview.NewTableCell(text).SetSelectable(false).
+		SetAttributes(tcell.AttrBold).
+		SetTextColor(tcell.ColorWhite).
+		SetBackgroundColor(tcell.ColorBlue)
+}
+
+func dataCell(v string, col int) *tview.TableCell {
+	text := v
+	if col > 0 {
+		text = " | " + v
+	}
+	return tview.NewTableCell(text).SetExpansion(1)
+}
+
+// placeholderCell stands in for a row that's been evicted from the window
+// (or never fetched yet), so it reads as "not loaded" rather than blank.
+func placeholderCell(col int) *tview.TableCell {
+	text := "…"
+	if col > 0 {
+		text = " | "
+	}
+	return tview.NewTableCell(text).SetExpansion(1).SetTextColor(tcell.ColorGray)
+}
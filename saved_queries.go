@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"pg_tui/store"
+)
+
+func (s *AppState) initSavedQueriesView() {
+	s.savedQueriesList = tview.NewList().ShowSecondaryText(true)
+	s.savedQueriesList.SetBorder(true).SetTitle(" Saved Queries ")
+	s.savedQueriesList.SetSelectedFunc(func(index int, mainText, secondary string, shortcut rune) {
+		s.loadSavedQuery(mainText)
+	})
+}
+
+// refreshSavedQueriesList repopulates the saved-queries panel with the
+// queries bound to the currently selected schema.
+func (s *AppState) refreshSavedQueriesList() {
+	s.savedQueriesList.Clear()
+	for i, q := range s.queries.ForSchema(s.currentSchema) {
+		s.savedQueriesList.AddItem(q.Name, q.SQL, rune('a'+(i%26)), nil)
+	}
+}
+
+// loadSavedQuery loads the named query (from the current schema) into the
+// query editor and marks it as just-run.
+func (s *AppState) loadSavedQuery(name string) {
+	for _, q := range s.queries.ForSchema(s.currentSchema) {
+		if q.Name == name {
+			s.queryArea.SetText(q.SQL, true)
+			if err := s.queries.Touch(q.Schema, q.Name); err != nil {
+				s.toast("touch saved query: %v", err)
+			}
+			s.app.SetFocus(s.queryArea)
+			return
+		}
+	}
+}
+
+func (s *AppState) deleteSelectedSavedQuery() {
+	queries := s.queries.ForSchema(s.currentSchema)
+	idx := s.savedQueriesList.GetCurrentItem()
+	if idx < 0 || idx >= len(queries) {
+		return
+	}
+	q := queries[idx]
+	if err := s.queries.Delete(q.Schema, q.Name); err != nil {
+		s.toast("delete saved query: %v", err)
+		return
+	}
+	s.refreshSavedQueriesList()
+	s.toast("Deleted saved query %q", q.Name)
+}
+
+// showSaveQueryModal prompts for a name and saves the current query editor
+// contents under it, bound to the currently selected schema.
+func (s *AppState) showSaveQueryModal() {
+	sql := s.queryArea.GetText()
+	if sql == "" {
+		s.toast("Nothing to save; the query editor is empty.")
+		return
+	}
+	if s.currentSchema == "" {
+		s.toast("Select a schema before saving a query.")
+		return
+	}
+
+	input := tview.NewInputField().
+		SetLabel("Save as: ").
+		SetFieldWidth(40)
+	input.SetBorder(true).SetTitle(fmt.Sprintf(" Save Query (schema: %s) ", s.currentSchema))
+	input.SetDoneFunc(func(key tcell.Key) {
+		defer s.hideModal()
+		if key != tcell.KeyEnter {
+			return
+		}
+		name := input.GetText()
+		if name == "" {
+			return
+		}
+		if err := s.queries.Save(store.Query{Name: name, Schema: s.currentSchema, SQL: sql}); err != nil {
+			s.toast("save query: %v", err)
+			return
+		}
+		s.refreshSavedQueriesList()
+		s.toast("Saved query %q", name)
+	})
+
+	s.showModal(input, 60, 3)
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"pg_tui/catalog"
+)
+
+// detailMode selects which view of the currently selected relation is shown
+// in the columns panel; 'i' in that panel cycles through them.
+type detailMode int
+
+const (
+	detailColumns detailMode = iota
+	detailIndexes
+	detailForeignKeys
+	detailChecks
+	detailModeCount
+)
+
+func (d detailMode) title() string {
+	switch d {
+	case detailIndexes:
+		return " Indexes (i: cycle) "
+	case detailForeignKeys:
+		return " Foreign Keys (i: cycle, g: goto) "
+	case detailChecks:
+		return " Check Constraints (i: cycle) "
+	default:
+		return " Columns (i: cycle) "
+	}
+}
+
+var relationGroups = []catalog.RelationKind{
+	catalog.Table, catalog.View, catalog.MaterializedView, catalog.Sequence, catalog.Function,
+}
+
+func relationGroupLabel(kind catalog.RelationKind) string {
+	switch kind {
+	case catalog.Table:
+		return "Tables"
+	case catalog.View:
+		return "Views"
+	case catalog.MaterializedView:
+		return "Materialized Views"
+	case catalog.Sequence:
+		return "Sequences"
+	case catalog.Function:
+		return "Functions"
+	default:
+		return string(kind)
+	}
+}
+
+// loadRelations replaces the relation tree with the tables, views,
+// materialized views, sequences, and functions defined in schema, grouped
+// by kind, and selects the first browsable relation found.
+func (s *AppState) loadRelations(schema string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rels, err := catalog.ListRelations(ctx, s.pool, schema)
+	if err != nil {
+		s.toast("load relations: %v", err)
+		return
+	}
+
+	byKind := make(map[catalog.RelationKind][]catalog.Relation)
+	for _, r := range rels {
+		byKind[r.Kind] = append(byKind[r.Kind], r)
+	}
+
+	root := s.relationTree.GetRoot()
+	root.ClearChildren()
+
+	var first *catalog.Relation
+	var firstNode *tview.TreeNode
+	for _, kind := range relationGroups {
+		group := byKind[kind]
+		if len(group) == 0 {
+			continue
+		}
+		groupNode := tview.NewTreeNode(fmt.Sprintf("%s (%d)", relationGroupLabel(kind), len(group))).
+			SetSelectable(true).SetExpanded(true)
+		root.AddChild(groupNode)
+		for i := range group {
+			rel := group[i]
+			node := tview.NewTreeNode(rel.Name).SetReference(rel).SetSelectable(true)
+			groupNode.AddChild(node)
+			if first == nil {
+				first, firstNode = &rel, node
+			}
+		}
+	}
+
+	if first == nil {
+		s.currentTable = ""
+		s.relationKind = ""
+		s.columnTable.Clear()
+		s.closeResultStream()
+		s.resultTable.Clear()
+		return
+	}
+	s.relationTree.SetCurrentNode(firstNode)
+	s.selectRelation(*first)
+}
+
+// selectRelation makes rel the active relation: it refreshes whichever
+// detail view is showing in the columns panel and, for previewable kinds,
+// streams a LIMIT preview into the results table.
+func (s *AppState) selectRelation(rel catalog.Relation) {
+	s.currentSchema = rel.Schema
+	s.currentTable = rel.Name
+	s.relationKind = rel.Kind
+	s.detailMode = detailColumns
+	s.refreshDetail()
+
+	switch rel.Kind {
+	case catalog.Table, catalog.View, catalog.MaterializedView, catalog.Sequence:
+		s.previewTable(rel.Schema, rel.Name)
+	case catalog.Function:
+		s.closeResultStream()
+		s.resultTable.Clear()
+		s.toast("Functions aren't previewable; showing columns/details only.")
+	}
+}
+
+// refreshDetail re-runs whichever detail query s.detailMode currently
+// selects against the active relation.
+func (s *AppState) refreshDetail() {
+	if s.currentTable == "" {
+		return
+	}
+	s.columnTable.SetTitle(s.detailMode.title())
+	// Rows are selectable only in the foreign keys view, where 'g' follows
+	// the selected row.
+	s.columnTable.SetSelectable(s.detailMode == detailForeignKeys, false)
+	switch s.detailMode {
+	case detailIndexes:
+		s.showIndexes()
+	case detailForeignKeys:
+		s.showForeignKeys()
+	case detailChecks:
+		s.showCheckConstraints()
+	default:
+		s.showColumns()
+	}
+}
+
+// cycleDetailView is bound to 'i' while the columns panel is focused; it
+// walks through columns -> indexes -> foreign keys -> check constraints.
+func (s *AppState) cycleDetailView() {
+	s.detailMode = (s.detailMode + 1) % detailModeCount
+	s.refreshDetail()
+}
+
+func (s *AppState) showColumns() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rows, err := s.pool.Query(ctx, `
+		SELECT column_name, data_type, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2
+		ORDER BY ordinal_position`, s.currentSchema, s.currentTable)
+	if err != nil {
+		s.toast("load columns: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	s.columnTable.Clear()
+	setHeader(s.columnTable, []string{"Column", "Type", "Nullable"})
+	row := 1
+	for rows.Next() {
+		var name, typ, nullable string
+		if err := rows.Scan(&name, &typ, &nullable); err != nil {
+			s.toast("scan: %v", err)
+			return
+		}
+		setRow(s.columnTable, row, []string{name, typ, nullable})
+		row++
+	}
+}
+
+func (s *AppState) showIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	idxs, err := catalog.ListIndexes(ctx, s.pool, s.currentSchema, s.currentTable)
+	if err != nil {
+		s.toast("load indexes: %v", err)
+		return
+	}
+
+	s.columnTable.Clear()
+	setHeader(s.columnTable, []string{"Index", "Definition"})
+	for i, idx := range idxs {
+		setRow(s.columnTable, i+1, []string{idx.Name, idx.Definition})
+	}
+}
+
+func (s *AppState) showForeignKeys() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	fks, err := catalog.ListForeignKeys(ctx, s.pool, s.currentSchema, s.currentTable)
+	if err != nil {
+		s.toast("load foreign keys: %v", err)
+		return
+	}
+	s.currentFKs = fks
+
+	s.columnTable.Clear()
+	setHeader(s.columnTable, []string{"Column", "References", "On Update", "On Delete"})
+	for i, fk := range fks {
+		ref := fmt.Sprintf("%s.%s.%s", fk.RefSchema, fk.RefTable, fk.RefColumn)
+		setRow(s.columnTable, i+1, []string{fk.Column, ref, fk.UpdateRule, fk.DeleteRule})
+	}
+}
+
+func (s *AppState) showCheckConstraints() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	checks, err := catalog.ListCheckConstraints(ctx, s.pool, s.currentSchema, s.currentTable)
+	if err != nil {
+		s.toast("load check constraints: %v", err)
+		return
+	}
+
+	s.columnTable.Clear()
+	setHeader(s.columnTable, []string{"Constraint", "Clause"})
+	for i, c := range checks {
+		setRow(s.columnTable, i+1, []string{c.ConstraintName, c.Clause})
+	}
+}
+
+// jumpToForeignKey is bound to 'g' while viewing the foreign keys detail
+// view: it follows the selected row to its referenced table, switching
+// schemas first if the reference points outside the current one.
+func (s *AppState) jumpToForeignKey() {
+	if s.detailMode != detailForeignKeys {
+		return
+	}
+	row, _ := s.columnTable.GetSelection()
+	idx := row - 1
+	if idx < 0 || idx >= len(s.currentFKs) {
+		return
+	}
+	fk := s.currentFKs[idx]
+
+	if fk.RefSchema != s.currentSchema {
+		s.currentSchema = fk.RefSchema
+		s.loadRelations(fk.RefSchema)
+	}
+
+	node := s.findRelationNode(fk.RefTable)
+	if node == nil {
+		s.toast("Couldn't find %s.%s in the tree.", fk.RefSchema, fk.RefTable)
+		return
+	}
+	rel, _ := node.GetReference().(catalog.Relation)
+	s.relationTree.SetCurrentNode(node)
+	s.selectRelation(rel)
+	s.app.SetFocus(s.relationTree)
+}
+
+// findRelationNode searches the relation tree's leaf nodes for one whose
+// relation name matches name.
+func (s *AppState) findRelationNode(name string) *tview.TreeNode {
+	var found *tview.TreeNode
+	s.relationTree.GetRoot().Walk(func(node, _ *tview.TreeNode) bool {
+		rel, ok := node.GetReference().(catalog.Relation)
+		if ok && rel.Name == name {
+			found = node
+			return false
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,149 @@
+// Package store persists a named-query library to a JSON file under
+// $XDG_CONFIG_HOME/dbtui/queries.json so saved queries survive restarts and
+// could later be shared across sessions.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Query is a single saved query, scoped to the schema it was written against.
+type Query struct {
+	Name    string    `json:"name"`
+	Schema  string    `json:"schema"`
+	SQL     string    `json:"sql"`
+	LastRun time.Time `json:"last_run"`
+}
+
+// QueryStore is a thread-safe, file-backed library of saved queries.
+type QueryStore struct {
+	mu      sync.Mutex
+	path    string
+	queries []Query
+}
+
+// Open loads the query library from $XDG_CONFIG_HOME/dbtui/queries.json,
+// falling back to ~/.config if XDG_CONFIG_HOME is unset. A missing file is
+// not an error; it just starts empty.
+func Open() (*QueryStore, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	qs := &QueryStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return qs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &qs.queries); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
+// NewMemory returns an in-memory-only store, used when the on-disk library
+// couldn't be opened so the rest of the UI doesn't have to special-case a nil
+// store.
+func NewMemory() *QueryStore {
+	return &QueryStore{}
+}
+
+func configPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dbtui", "queries.json"), nil
+}
+
+// ForSchema returns the queries saved for schema, sorted by name.
+func (qs *QueryStore) ForSchema(schema string) []Query {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	var out []Query
+	for _, q := range qs.queries {
+		if q.Schema == schema {
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Save upserts q by (schema, name) and persists the library to disk.
+func (qs *QueryStore) Save(q Query) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	q.LastRun = time.Now()
+	for i := range qs.queries {
+		if qs.queries[i].Schema == q.Schema && qs.queries[i].Name == q.Name {
+			qs.queries[i] = q
+			return qs.persistLocked()
+		}
+	}
+	qs.queries = append(qs.queries, q)
+	return qs.persistLocked()
+}
+
+// Touch updates the last-run timestamp for a saved query, e.g. when it's
+// loaded back into the query editor and re-run.
+func (qs *QueryStore) Touch(schema, name string) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	for i := range qs.queries {
+		if qs.queries[i].Schema == schema && qs.queries[i].Name == name {
+			qs.queries[i].LastRun = time.Now()
+			return qs.persistLocked()
+		}
+	}
+	return nil
+}
+
+// Delete removes the saved query identified by (schema, name).
+func (qs *QueryStore) Delete(schema, name string) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	out := qs.queries[:0]
+	for _, q := range qs.queries {
+		if q.Schema == schema && q.Name == name {
+			continue
+		}
+		out = append(out, q)
+	}
+	qs.queries = out
+	return qs.persistLocked()
+}
+
+// persistLocked writes the library to disk. It's a no-op for in-memory
+// stores created via NewMemory.
+func (qs *QueryStore) persistLocked() error {
+	if qs.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(qs.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(qs.queries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(qs.path, data, 0o644)
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"pg_tui/catalog"
 )
 
 func (s *AppState) initUI() {
@@ -13,15 +15,20 @@ func (s *AppState) initUI() {
 	s.schemaList.SetBorder(true).SetTitle(" Schemas ")
 	s.schemaList.SetSelectedFunc(func(index int, mainText, secondary string, shortcut rune) {
 		s.currentSchema = mainText
-		s.loadTables(mainText)
+		s.loadRelations(mainText)
+		s.refreshSavedQueriesList()
 	})
 
-	s.tableList = tview.NewList().ShowSecondaryText(false)
-	s.tableList.SetBorder(true).SetTitle(" Tables ")
-	s.tableList.SetSelectedFunc(func(index int, mainText, secondary string, shortcut rune) {
-		s.currentTable = mainText
-		s.loadColumns(s.currentSchema, s.currentTable)
-		s.previewTable(s.currentSchema, s.currentTable)
+	root := tview.NewTreeNode(" ").SetSelectable(false)
+	s.relationTree = tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+	s.relationTree.SetBorder(true).SetTitle(" Tables / Views / Sequences / Functions ")
+	s.relationTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		rel, ok := node.GetReference().(catalog.Relation)
+		if !ok {
+			node.SetExpanded(!node.IsExpanded())
+			return
+		}
+		s.selectRelation(rel)
 	})
 
 	s.columnTable = tview.NewTable().SetBorders(false)
@@ -30,6 +37,9 @@ func (s *AppState) initUI() {
 	s.resultTable = tview.NewTable().SetFixed(1, 0)
 	s.resultTable.SetBorder(true).SetTitle(" Results / Preview ")
 
+	s.initPlanView()
+	s.initSavedQueriesView()
+
 	// Correctly initialize TextArea
 	s.queryArea = tview.NewTextArea()
 	s.queryArea.SetPlaceholder("Enter SQL query here (F5 to run)")
@@ -42,6 +52,17 @@ func (s *AppState) initUI() {
 			s.runAdhocQuery(s.queryArea.GetText())
 			return nil
 		}
+		// Up/Down at the first/last line walk backward/forward through
+		// history, like a shell; anywhere else in a multi-line query they
+		// just move the cursor as usual.
+		if event.Key() == tcell.KeyUp && s.atFirstLine() {
+			s.historyUp()
+			return nil
+		}
+		if event.Key() == tcell.KeyDown && s.atLastLine() {
+			s.historyDown()
+			return nil
+		}
 		return event
 	})
 
@@ -50,11 +71,17 @@ func (s *AppState) initUI() {
 
 	left := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(s.schemaList, 0, 1, true).
-		AddItem(s.tableList, 0, 1, false)
+		AddItem(s.relationTree, 0, 1, false).
+		AddItem(s.savedQueriesList, 0, 1, false)
+
+	planRow := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(s.planTree, 0, 1, false).
+		AddItem(s.planDetail, 0, 1, false)
 
 	right := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(s.columnTable, 0, 1, false).
 		AddItem(s.resultTable, 0, 3, false).
+		AddItem(planRow, 0, 2, false).
 		AddItem(s.queryArea, 5, 0, false). // Increased size for multiline
 		AddItem(s.statusBar, 1, 0, false)
 
@@ -62,46 +89,111 @@ func (s *AppState) initUI() {
 		AddItem(left, 35, 0, true).
 		AddItem(right, 0, 1, false)
 
-	// Global keybindings
-	s.app.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
-		// Only handle global keybindings if the query input is not focused
-		if s.app.GetFocus() != s.queryArea {
-			switch ev.Rune() {
-			case 'q', 'Q':
-				s.app.Stop()
-				return nil
-			case 'r', 'R':
-				s.loadSchemas()
-				s.toast("Refreshed schemas and tables.")
-				return nil
-			}
+	s.pages = tview.NewPages().AddPage("main", s.layout, true, true)
+}
+
+// handleKey implements this tab's keybindings. It's called by the owning
+// App's global input capture whenever a key isn't claimed by tab management
+// (new/close/switch), so each tab behaves exactly as it did as a standalone
+// single-connection app.
+func (s *AppState) handleKey(ev *tcell.EventKey) *tcell.EventKey {
+	// While a modal (save query, export, history search) is open, keys go to
+	// the modal itself; only Ctrl-C still cancels the running query.
+	if name, _ := s.pages.GetFrontPage(); name == "modal" {
+		if ev.Key() == tcell.KeyCtrlC {
+			s.cancelRunningQuery()
+			return nil
+		}
+		return ev
+	}
+
+	// Only handle these if the query input is not focused
+	if s.app.GetFocus() != s.queryArea {
+		switch ev.Rune() {
+		case 'q', 'Q':
+			s.app.Stop()
+			return nil
+		case 'r', 'R':
+			s.loadSchemas()
+			s.toast("Refreshed schemas and tables.")
+			return nil
 		}
+	}
 
-		// Handle F5 and Tab regardless of focus
-		switch ev.Key() {
-		case tcell.KeyF5:
-			s.runAdhocQuery(s.queryArea.GetText())
+	// Handle F5, F6, Ctrl-S/D and Tab regardless of focus
+	switch ev.Key() {
+	case tcell.KeyF5:
+		s.runAdhocQuery(s.queryArea.GetText())
+		return nil
+	case tcell.KeyF6:
+		s.runExplainPlan(s.queryArea.GetText())
+		return nil
+	case tcell.KeyCtrlS:
+		s.showSaveQueryModal()
+		return nil
+	case tcell.KeyCtrlD:
+		s.deleteSelectedSavedQuery()
+		return nil
+	case tcell.KeyCtrlC:
+		s.cancelRunningQuery()
+		return nil
+	case tcell.KeyCtrlE:
+		s.showExportModal()
+		return nil
+	case tcell.KeyCtrlR:
+		s.showHistorySearch()
+		return nil
+	case tcell.KeyTab:
+		s.cycleFocus()
+		return nil
+	}
+	if s.app.GetFocus() == s.columnTable {
+		switch ev.Rune() {
+		case 'i', 'I':
+			s.cycleDetailView()
 			return nil
-		case tcell.KeyTab:
-			s.cycleFocus()
+		case 'g', 'G':
+			s.jumpToForeignKey()
 			return nil
 		}
-		return ev
-	})
+	}
+	return ev
+}
+
+// showModal centers p over the main layout as a fixed-size overlay page.
+func (s *AppState) showModal(p tview.Primitive, width, height int) {
+	modal := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(p, width, 0, true).
+			AddItem(nil, 0, 1, false), height, 0, true).
+		AddItem(nil, 0, 1, false)
+	s.pages.AddPage("modal", modal, true, true)
+	s.app.SetFocus(p)
+}
 
-	s.app.SetRoot(s.layout, true)
+func (s *AppState) hideModal() {
+	s.pages.RemovePage("modal")
+	s.app.SetFocus(s.queryArea)
 }
 
 func (s *AppState) cycleFocus() {
 	p := s.app.GetFocus()
 	switch p {
 	case s.schemaList:
-		s.app.SetFocus(s.tableList)
-	case s.tableList:
+		s.app.SetFocus(s.relationTree)
+	case s.relationTree:
 		s.app.SetFocus(s.columnTable)
 	case s.columnTable:
 		s.app.SetFocus(s.resultTable)
 	case s.resultTable:
+		s.app.SetFocus(s.planTree)
+	case s.planTree:
+		s.app.SetFocus(s.planDetail)
+	case s.planDetail:
+		s.app.SetFocus(s.savedQueriesList)
+	case s.savedQueriesList:
 		s.app.SetFocus(s.queryArea)
 	default:
 		s.app.SetFocus(s.schemaList)
@@ -114,7 +206,7 @@ func (s *AppState) toast(format string, args ...any) {
 	go func() {
 		time.Sleep(3 * time.Second)
 		s.app.QueueUpdateDraw(func() {
-			s.updateStatus("F5: Run | q: Quit | r: Refresh | Tab: Cycle Focus")
+			s.updateStatus("F5: Run | F6: Explain | Ctrl-S: Save | Ctrl-D: Delete | Ctrl-E: Export | Ctrl-R: History | i: Indexes/FKs | g: Goto FK | q: Quit | r: Refresh | Tab: Cycle Focus")
 		})
 	}()
 }